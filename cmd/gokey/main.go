@@ -3,12 +3,14 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/dannyfast/gokey"
@@ -16,21 +18,24 @@ import (
 )
 
 var (
-	pass, passFile, keyType, seedPath, realm, output string
-	unsafe                                           bool
-	seedSkipCount, length                            int
+	pass, passFile, keyType, seedPath, realm, realmsPath, output, cryptsetupDevice, vaultID string
+	unsafe                                                                                  bool
+	seedSkipCount, length                                                                   int
 )
 
 func init() {
 	flag.StringVar(&pass, "p", "", "master password (if not specified, will be asked interactively)")
 	flag.StringVar(&passFile, "P", "", "master password file (if not specified, will be asked interactively)")
-	flag.StringVar(&keyType, "t", "pass", "output type (can be pass, seed, raw, ec256, ec384, ec521, rsa2048, rsa4096, x25519, ed25519)")
+	flag.StringVar(&keyType, "t", "pass", "output type (can be pass, seed, ansible-vault, raw, keyfile, fingerprint, netrc, pgpass, mycnf, ec256, ec384, ec521, rsa2048, rsa4096, x25519, ed25519)")
 	flag.StringVar(&seedPath, "s", "", "path to master seed file (optional)")
 	flag.IntVar(&seedSkipCount, "skip", 0, "number of bytes to skip from master seed file (default 0)")
 	flag.StringVar(&realm, "r", "", "password/key realm (most probably purpose of the password/key)")
+	flag.StringVar(&realmsPath, "c", "", `path to realms config with host/user metadata (only for "netrc", "pgpass" and "mycnf" types)`)
 	flag.StringVar(&output, "o", "", "output path to store generated key/password (default stdout)")
+	flag.StringVar(&cryptsetupDevice, "cryptsetup", "", "print cryptsetup command to add the generated keyfile to the specified LUKS device (only for \"keyfile\" type)")
+	flag.StringVar(&vaultID, "vault-id", "default", "Ansible vault id to generate the vault password for (only for \"ansible-vault\" type)")
 	flag.BoolVar(&unsafe, "u", false, "UNSAFE: allow key generation without a seed")
-	flag.IntVar(&length, "l", 10, `number of characters in the generated password or number of bytes in the generated raw stream (default 10 for "pass" type and 32 for "raw" type)`)
+	flag.IntVar(&length, "l", 10, `number of characters in the generated password, vault password or credential file passwords or number of bytes in the generated raw stream, keyfile or fingerprint (default 10 for "pass" and credential file types, 32 for "ansible-vault" and "raw" types, 4096 for "keyfile" type and 8 for "fingerprint" type)`)
 }
 
 var keyTypes = map[string]gokey.KeyType{
@@ -43,6 +48,39 @@ var keyTypes = map[string]gokey.KeyType{
 	"ed25519": gokey.ED25519,
 }
 
+var credFormats = map[string]gokey.CredentialFormat{
+	"netrc":  gokey.Netrc,
+	"pgpass": gokey.PgPass,
+	"mycnf":  gokey.MyCnf,
+}
+
+func readRealms() []gokey.Credential {
+	content, err := ioutil.ReadFile(realmsPath)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var creds []gokey.Credential
+	err = json.Unmarshal(content, &creds)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	for _, cred := range creds {
+		if cred.Realm == "" {
+			log.Fatalf("no realm provided for host %v in realms config\n", cred.Host)
+		}
+	}
+
+	// validate entries without passwords, derived passwords never break the format
+	err = gokey.EncodeCredentials(creds, credFormats[keyType], ioutil.Discard)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	return creds
+}
+
 func genSeed(w io.Writer) {
 	seed, err := gokey.GenerateEncryptedKeySeed(pass)
 	if err != nil {
@@ -67,6 +105,33 @@ func genPass(seed []byte, w io.Writer) {
 	}
 }
 
+func genAnsibleVaultPass(seed []byte, w io.Writer) {
+	password, err := gokey.GetAnsibleVaultPass(pass, realm, vaultID, seed, &gokey.PasswordSpec{length, 3, 3, 1, 1, ""})
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, password)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+func genCredentials(creds []gokey.Credential, seed []byte, w io.Writer) {
+	for i := range creds {
+		password, err := gokey.GetPass(pass, creds[i].Realm, seed, &gokey.PasswordSpec{length, 3, 3, 1, 1, ""})
+		if err != nil {
+			log.Fatalln(err)
+		}
+		creds[i].Password = password
+	}
+
+	err := gokey.EncodeCredentials(creds, credFormats[keyType], w)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
 func genKey(seed []byte, w io.Writer) {
 	key, err := gokey.GetKey(pass, realm, seed, keyTypes[keyType], unsafe)
 	if err != nil {
@@ -91,6 +156,44 @@ func genRaw(seed []byte, w io.Writer) {
 	}
 }
 
+func genKeyfile(seed []byte, w io.Writer) {
+	keyfile, err := gokey.GetKeyfile(pass, realm, seed, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.CopyN(w, keyfile, int64(length))
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// shellQuote quotes s for safe use in a POSIX shell command line
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+func printCryptsetup() {
+	keyfilePath, err := filepath.Abs(output)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	fmt.Fprintf(os.Stderr, "cryptsetup luksAddKey %v %v\n", shellQuote(cryptsetupDevice), shellQuote(keyfilePath))
+}
+
+func genFingerprint(seed []byte, w io.Writer) {
+	fp, err := gokey.GetFingerprint(pass, realm, seed, length, unsafe)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	_, err = io.WriteString(w, fp)
+	if err != nil {
+		log.Fatalln(err)
+	}
+}
+
 func isFlagSet(name string) bool {
 	found := false
 	flag.Visit(func(f *flag.Flag) {
@@ -110,6 +213,36 @@ func logFatal(format string, args ...interface{}) {
 func main() {
 	flag.Parse()
 
+	if isFlagSet("vault-id") && keyType != "ansible-vault" {
+		logFatal("vault-id parameter is only supported for ansible-vault type")
+	}
+
+	_, isCredFormat := credFormats[keyType]
+	if isCredFormat != (realmsPath != "") {
+		logFatal("realms config is required for and only supported by netrc, pgpass and mycnf types")
+	}
+	if isCredFormat && isFlagSet("r") {
+		logFatal("type %v does not support realm parameter, realms are read from the realms config", keyType)
+	}
+
+	if isFlagSet("cryptsetup") {
+		if cryptsetupDevice == "" {
+			logFatal("invalid cryptsetup parameter")
+		}
+		if keyType != "keyfile" {
+			logFatal("cryptsetup parameter is only supported for keyfile type")
+		}
+		if output == "" {
+			logFatal("cryptsetup parameter requires an output path for the keyfile")
+		}
+	}
+
+	// read the realms config before the output file gets truncated
+	var creds []gokey.Credential
+	if isCredFormat {
+		creds = readRealms()
+	}
+
 	pass = os.Getenv("GOKEY_MASTER")
 
 	var err error
@@ -168,7 +301,7 @@ func main() {
 	if keyType == "seed" {
 		genSeed(out)
 	} else {
-		if realm == "" {
+		if realm == "" && !isCredFormat {
 			logFatal("no realm provided")
 		}
 
@@ -192,6 +325,15 @@ func main() {
 			}
 			genPass(seed, out)
 			fmt.Fprintln(os.Stderr, "")
+		case "ansible-vault":
+			if !isFlagSet("l") {
+				length = 32
+			}
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			genAnsibleVaultPass(seed, out)
+			fmt.Fprintln(os.Stderr, "")
 		case "raw":
 			if !isFlagSet("l") {
 				length = 32
@@ -200,6 +342,31 @@ func main() {
 				logFatal("invalid length parameter")
 			}
 			genRaw(seed, out)
+		case "keyfile":
+			if !isFlagSet("l") {
+				length = 4096
+			}
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			genKeyfile(seed, out)
+			if isFlagSet("cryptsetup") {
+				printCryptsetup()
+			}
+		case "fingerprint":
+			if !isFlagSet("l") {
+				length = 8
+			}
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			genFingerprint(seed, out)
+			fmt.Fprintln(os.Stderr, "")
+		case "netrc", "pgpass", "mycnf":
+			if length <= 0 {
+				logFatal("invalid length parameter")
+			}
+			genCredentials(creds, seed, out)
 		default:
 			if _, ok := keyTypes[keyType]; !ok {
 				logFatal("unknown key type: %v", keyType)
@@ -0,0 +1,169 @@
+package gokey
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+type CredentialFormat int
+
+const (
+	Netrc CredentialFormat = iota
+	PgPass
+	MyCnf
+)
+
+// Credential describes a single entry in a credential file. Password is not
+// part of the realms config and is expected to be derived from Realm.
+type Credential struct {
+	Realm    string `json:"realm"`
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"`
+	User     string `json:"user"`
+	Database string `json:"database,omitempty"`
+	// option group for MySQL option files (default "client")
+	Group    string `json:"group,omitempty"`
+	Password string `json:"-"`
+}
+
+func hasSpace(s string) bool {
+	return strings.IndexFunc(s, unicode.IsSpace) >= 0
+}
+
+// curl only handles backslash escapes inside double-quoted netrc tokens
+func netrcQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func encodeNetrc(creds []Credential, w io.Writer) error {
+	for _, cred := range creds {
+		if cred.Host == "" || hasSpace(cred.Host) || strings.ContainsAny(cred.Host, `"\`) {
+			return fmt.Errorf("invalid netrc machine %q for realm %v", cred.Host, cred.Realm)
+		}
+
+		// quoted tokens may contain spaces, but not line breaks
+		if cred.User == "" || strings.ContainsAny(cred.User+cred.Password, "\r\n") {
+			return fmt.Errorf("invalid netrc entry for realm %v", cred.Realm)
+		}
+
+		_, err := fmt.Fprintf(w, "machine %v login %v password %v\n", cred.Host, netrcQuote(cred.User), netrcQuote(cred.Password))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// see https://www.postgresql.org/docs/current/libpq-pgpass.html
+func pgpassEscape(s string) string {
+	if s == "" {
+		return "*"
+	}
+
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`).Replace(s)
+}
+
+func encodePgPass(creds []Credential, w io.Writer) error {
+	for _, cred := range creds {
+		if cred.User == "" || strings.ContainsAny(cred.Host+cred.Port+cred.Database+cred.User+cred.Password, "\r\n") {
+			return fmt.Errorf("invalid pgpass entry for realm %v", cred.Realm)
+		}
+
+		_, err := fmt.Fprintf(w, "%v:%v:%v:%v:%v\n", pgpassEscape(cred.Host), pgpassEscape(cred.Port), pgpassEscape(cred.Database), pgpassEscape(cred.User), pgpassEscape(cred.Password))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// see https://dev.mysql.com/doc/refman/8.0/en/option-files.html
+func mycnfQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func encodeMyCnf(creds []Credential, w io.Writer) error {
+	groups := make(map[string]bool)
+
+	for i, cred := range creds {
+		group := cred.Group
+		if group == "" {
+			group = "client"
+		}
+
+		if groups[group] {
+			return fmt.Errorf("duplicate option group %v", group)
+		}
+		groups[group] = true
+
+		if hasSpace(group) || strings.ContainsAny(group, "[]") {
+			return fmt.Errorf("invalid option group %q", group)
+		}
+
+		if i > 0 {
+			_, err := io.WriteString(w, "\n")
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err := fmt.Fprintf(w, "[%v]\n", group)
+		if err != nil {
+			return err
+		}
+
+		for _, opt := range []struct{ name, value string }{
+			{"host", cred.Host},
+			{"port", cred.Port},
+			{"user", cred.User},
+			{"password", cred.Password},
+			{"database", cred.Database},
+		} {
+			if opt.value == "" {
+				continue
+			}
+
+			if strings.ContainsAny(opt.value, "\r\n") {
+				return fmt.Errorf("invalid %v option for realm %v", opt.name, cred.Realm)
+			}
+
+			_, err = fmt.Fprintf(w, "%v=%v\n", opt.name, mycnfQuote(opt.value))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func EncodeCredentials(creds []Credential, format CredentialFormat, w io.Writer) error {
+	// render everything first, so an invalid entry does not leave a
+	// partially written credential file behind
+	var buf bytes.Buffer
+	var err error
+
+	switch format {
+	case Netrc:
+		err = encodeNetrc(creds, &buf)
+	case PgPass:
+		err = encodePgPass(creds, &buf)
+	case MyCnf:
+		err = encodeMyCnf(creds, &buf)
+	default:
+		err = errors.New("invalid credential format requested")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,117 @@
+package gokey
+
+import (
+	"strings"
+	"testing"
+)
+
+func encodeCredentials(t *testing.T, creds []Credential, format CredentialFormat) string {
+	var b strings.Builder
+
+	err := EncodeCredentials(creds, format, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return b.String()
+}
+
+func TestEncodeNetrc(t *testing.T) {
+	creds := []Credential{
+		{Realm: "example.com", Host: "example.com", User: "alice", Password: `pa"s\s`},
+		{Realm: "example2.com", Host: "example2.com", User: "bob", Password: "pass"},
+	}
+
+	expected := `machine example.com login "alice" password "pa\"s\\s"
+machine example2.com login "bob" password "pass"
+`
+	if netrc := encodeCredentials(t, creds, Netrc); netrc != expected {
+		t.Fatalf("unexpected netrc output: %q", netrc)
+	}
+
+	expected = "machine example.com login \"alice bob\" password \"pass\"\n"
+	if netrc := encodeCredentials(t, []Credential{{Realm: "example.com", Host: "example.com", User: "alice bob", Password: "pass"}}, Netrc); netrc != expected {
+		t.Fatalf("unexpected netrc output: %q", netrc)
+	}
+
+	var b strings.Builder
+	err := EncodeCredentials([]Credential{{Realm: "example.com", Host: "example.com", User: "alice\nmachine evil.com", Password: "pass"}}, Netrc, &b)
+	if err == nil {
+		t.Fatal("allowed newline in netrc token")
+	}
+
+	if !strings.Contains(err.Error(), "example.com") {
+		t.Fatalf("netrc error does not name the realm: %v", err)
+	}
+}
+
+func TestEncodePgPass(t *testing.T) {
+	creds := []Credential{
+		{Realm: "db", Host: "db.example.com", Port: "5432", Database: "app", User: "alice", Password: `pa:s\s`},
+		{Realm: "db2", User: "bob", Password: "pass"},
+	}
+
+	expected := "db.example.com:5432:app:alice:pa\\:s\\\\s\n*:*:*:bob:pass\n"
+	if pgpass := encodeCredentials(t, creds, PgPass); pgpass != expected {
+		t.Fatalf("unexpected pgpass output: %q", pgpass)
+	}
+
+	for _, cred := range []Credential{
+		{Realm: "db", Host: "db.example.com\n*", User: "alice", Password: "pass"},
+		{Realm: "db", Port: "5432\r\n*", User: "alice", Password: "pass"},
+		{Realm: "db", Database: "app\n*", User: "alice", Password: "pass"},
+	} {
+		var b strings.Builder
+		err := EncodeCredentials([]Credential{cred}, PgPass, &b)
+		if err == nil {
+			t.Fatalf("allowed newline in pgpass entry %+v", cred)
+		}
+	}
+}
+
+func TestEncodeInvalidTrailingEntry(t *testing.T) {
+	creds := []Credential{
+		{Realm: "a", Host: "a.example.com", User: "alice", Password: "pass"},
+		{Realm: "b", Host: "b.example.com", Password: "pass", Group: "client"},
+	}
+
+	for _, format := range []CredentialFormat{Netrc, PgPass, MyCnf} {
+		var b strings.Builder
+		err := EncodeCredentials(creds, format, &b)
+		if err == nil {
+			t.Fatalf("allowed invalid entry for format %v", format)
+		}
+
+		if b.Len() != 0 {
+			t.Fatalf("partial output for format %v: %q", format, b.String())
+		}
+	}
+}
+
+func TestEncodeMyCnf(t *testing.T) {
+	creds := []Credential{
+		{Realm: "db", Host: "db.example.com", User: "alice", Password: `pa"s\s`},
+		{Realm: "db2", Host: "db2.example.com", Port: "3307", User: "bob", Password: "pass", Group: "clientdb2"},
+	}
+
+	expected := `[client]
+host="db.example.com"
+user="alice"
+password="pa\"s\\s"
+
+[clientdb2]
+host="db2.example.com"
+port="3307"
+user="bob"
+password="pass"
+`
+	if mycnf := encodeCredentials(t, creds, MyCnf); mycnf != expected {
+		t.Fatalf("unexpected option file output: %q", mycnf)
+	}
+
+	var b strings.Builder
+	err := EncodeCredentials(append(creds, Credential{Realm: "db3", User: "carol"}), MyCnf, &b)
+	if err == nil {
+		t.Fatal("allowed duplicate option groups")
+	}
+}
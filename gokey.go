@@ -7,6 +7,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/hex"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -43,6 +44,16 @@ func GetPass(password, realm string, seed []byte, spec *PasswordSpec) (string, e
 	return gen.GeneratePassword(spec)
 }
 
+func GetAnsibleVaultPass(password, realm, vaultID string, seed []byte, spec *PasswordSpec) (string, error) {
+	rng, err := getReader(password, realm+fmt.Sprintf("-ansible-vault(%v)", vaultID), seed, true)
+	if err != nil {
+		return "", err
+	}
+
+	gen := &KeyGen{rng}
+	return gen.GeneratePassword(spec)
+}
+
 func GetKey(password, realm string, seed []byte, kt KeyType, allowUnsafe bool) (crypto.PrivateKey, error) {
 	rng, err := getReader(password, realm+fmt.Sprintf("-key(%v)", kt), seed, allowUnsafe)
 	if err != nil {
@@ -62,6 +73,34 @@ func GetRaw(password, realm string, seed []byte, allowUnsafe bool) (io.Reader, e
 	return rng, nil
 }
 
+func GetKeyfile(password, realm string, seed []byte, allowUnsafe bool) (io.Reader, error) {
+	rng, err := getReader(password, realm+"-keyfile", seed, allowUnsafe)
+	if err != nil {
+		return nil, err
+	}
+
+	return rng, nil
+}
+
+func GetFingerprint(password, realm string, seed []byte, length int, allowUnsafe bool) (string, error) {
+	if length <= 0 {
+		return "", errors.New("invalid fingerprint length")
+	}
+
+	rng, err := getReader(password, realm+"-fingerprint", seed, allowUnsafe)
+	if err != nil {
+		return "", err
+	}
+
+	fp := make([]byte, length)
+	_, err = io.ReadFull(rng, fp)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(fp), nil
+}
+
 // below code implements asn1 encoding of x25519 and ed25519 keys according
 // to https://tools.ietf.org/id/draft-ietf-curdle-pkix-10.txt
 // the output should be compatible to OpenSSL pkey functions
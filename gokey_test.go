@@ -6,6 +6,7 @@ import (
 	"encoding/asn1"
 	"encoding/hex"
 	"encoding/pem"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
@@ -84,6 +85,178 @@ func TestGetPass(t *testing.T) {
 	}
 }
 
+func TestGetKeyfile(t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readAll := func(r io.Reader) []byte {
+		buf := make([]byte, 4096)
+		_, err := io.ReadFull(r, buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return buf
+	}
+
+	keyfile1, err := GetKeyfile("pass1", "disk", seed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw1, err := GetRaw("pass1", "disk", seed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyfile1Retry, err := GetKeyfile("pass1", "disk", seed, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyfile1Bytes := readAll(keyfile1)
+
+	if bytes.Compare(keyfile1Bytes, readAll(raw1)) == 0 {
+		t.Fatal("keyfile matches raw stream for the same realm")
+	}
+
+	if bytes.Compare(keyfile1Bytes, readAll(keyfile1Retry)) != 0 {
+		t.Fatal("keyfiles with same invocation options do not match")
+	}
+
+	_, err = GetKeyfile("pass1", "disk", nil, false)
+	if err == nil {
+		t.Fatal("allowed unsafe keyfile generation")
+	}
+}
+
+func TestGetFingerprint(t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp1, err := GetFingerprint("pass1", "example.com", seed, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fp1) != 16 {
+		t.Fatalf("unexpected fingerprint length %v", len(fp1))
+	}
+
+	rng, err := NewDRNGwithSeed("pass1", "example.com-fingerprint", seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stream := make([]byte, 8)
+	_, err = io.ReadFull(rng, stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(fp1, hex.EncodeToString(stream)) != 0 {
+		t.Fatal("fingerprint does not match the fingerprint stream")
+	}
+
+	seed2, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp1Seed2, err := GetFingerprint("pass1", "example.com", seed2, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(fp1, fp1Seed2) == 0 {
+		t.Fatal("fingerprints match for different seeds")
+	}
+
+	fp1Pass2, err := GetFingerprint("pass2", "example.com", nil, 8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp1Pass1, err := GetFingerprint("pass1", "example.com", nil, 8, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(fp1Pass1, fp1Pass2) == 0 {
+		t.Fatal("fingerprints match for different master passwords")
+	}
+
+	fp2, err := GetFingerprint("pass1", "example2.com", seed, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(fp1, fp2) == 0 {
+		t.Fatal("fingerprints match for different realms")
+	}
+
+	fp1Retry, err := GetFingerprint("pass1", "example.com", seed, 8, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(fp1, fp1Retry) != 0 {
+		t.Fatal("fingerprints with same invocation options do not match")
+	}
+
+	_, err = GetFingerprint("pass1", "example.com", nil, 8, false)
+	if err == nil {
+		t.Fatal("allowed unsafe fingerprint generation")
+	}
+
+	_, err = GetFingerprint("pass1", "example.com", seed, 0, false)
+	if err == nil {
+		t.Fatal("allowed zero length fingerprint")
+	}
+}
+
+func TestGetAnsibleVaultPass(t *testing.T) {
+	seed, err := GenerateEncryptedKeySeed("pass1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vaultDefault, err := GetAnsibleVaultPass("pass1", "ansible", "default", seed, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vaultDev, err := GetAnsibleVaultPass("pass1", "ansible", "dev", seed, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(vaultDefault, vaultDev) == 0 {
+		t.Fatal("vault passwords match for different vault ids")
+	}
+
+	pass1, err := GetPass("pass1", "ansible", seed, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(vaultDefault, pass1) == 0 {
+		t.Fatal("vault password matches regular password for the same realm")
+	}
+
+	vaultDefaultRetry, err := GetAnsibleVaultPass("pass1", "ansible", "default", seed, passSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Compare(vaultDefault, vaultDefaultRetry) != 0 {
+		t.Fatal("vault passwords with same invocation options do not match")
+	}
+}
+
 func keyToBytes(key crypto.PrivateKey, t *testing.T) []byte {
 	buf := bytes.NewBuffer(nil)
 